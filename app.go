@@ -0,0 +1,210 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zubairtahit/buggy_project/config"
+	"github.com/zubairtahit/buggy_project/internal/httpx"
+)
+
+// CreateUserRequest is the payload accepted by (*App).CreateUser.
+type CreateUserRequest struct {
+	Name string `json:"name" validate:"required,min=3,max=20,alphanum"`
+}
+
+// App bundles the dependencies handlers need, replacing the old
+// package-level db global so the server can be constructed against a real
+// database or a mock in tests.
+type App struct {
+	DB     *sql.DB
+	Logger *log.Logger
+	Config config.Config
+	Router http.Handler
+}
+
+// Options are the dependencies passed to NewApp.
+type Options struct {
+	DB     *sql.DB
+	Logger *log.Logger
+	Config config.Config
+}
+
+// NewApp wires an App's routes and returns it along with a teardown
+// function the caller should defer to release its resources.
+func NewApp(opts Options) (*App, func(), error) {
+	if opts.DB == nil {
+		return nil, nil, fmt.Errorf("app: DB is required")
+	}
+	if err := checkJWTConfig(); err != nil {
+		return nil, nil, err
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	app := &App{
+		DB:     opts.DB,
+		Logger: logger,
+		Config: opts.Config,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", app.route(app.GetUsers))
+	mux.HandleFunc("/create", app.route(AuthMiddleware(RequireRole(RoleAdmin, app.CreateUser))))
+	mux.HandleFunc("/register", app.route(app.Register))
+	mux.HandleFunc("/login", app.route(app.Login))
+	mux.HandleFunc("/healthz", app.route(app.Healthz))
+	app.Router = mux
+
+	teardown := func() {
+		app.DB.Close()
+	}
+
+	return app, teardown, nil
+}
+
+// route adapts an httpx.APIHandler into an http.HandlerFunc by running it
+// through httpx.Invoke.
+func (a *App) route(handler httpx.APIHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		httpx.Invoke(w, r, handler)
+	}
+}
+
+// GetUsers handles HTTP requests to retrieve a page of users, optionally
+// filtered by name prefix, using keyset pagination for stable performance
+// on large tables.
+func (a *App) GetUsers(r *http.Request) (interface{}, error) {
+	limit := defaultUsersLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return nil, httpx.BadRequest("limit must be a positive integer")
+		}
+		if n > maxUsersLimit {
+			n = maxUsersLimit
+		}
+		limit = n
+	}
+
+	afterID := 0
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		c, err := decodeUsersCursor(raw)
+		if err != nil {
+			return nil, httpx.BadRequest("invalid cursor")
+		}
+		afterID = c.LastID
+	}
+
+	namePrefix := r.URL.Query().Get("name_prefix")
+
+	query := "SELECT id, name, created_at FROM users WHERE id > $1"
+	args := []interface{}{afterID}
+	if namePrefix != "" {
+		query += " AND name LIKE $2"
+		args = append(args, namePrefix+"%")
+	}
+	query += fmt.Sprintf(" ORDER BY id ASC LIMIT %d", limit)
+
+	rows, err := a.DB.Query(query, args...)
+	if err != nil {
+		a.Logger.Println("Failed to query users: ", err)
+		return nil, httpx.Internal(err)
+	}
+	defer rows.Close()
+
+	var users []map[string]interface{}
+	var last usersCursor
+	for rows.Next() {
+		var id int
+		var name string
+		var createdAt time.Time
+		if err := rows.Scan(&id, &name, &createdAt); err != nil {
+			a.Logger.Println("Failed to scan user: ", err)
+			return nil, httpx.Internal(err)
+		}
+		users = append(users, map[string]interface{}{
+			"id":         id,
+			"name":       name,
+			"created_at": createdAt,
+		})
+		last = usersCursor{LastID: id, CreatedAt: createdAt}
+	}
+
+	if err := rows.Err(); err != nil {
+		a.Logger.Println("Error iterating users: ", err)
+		return nil, httpx.Internal(err)
+	}
+
+	nextCursor := ""
+	if len(users) == limit {
+		nextCursor, err = encodeUsersCursor(last)
+		if err != nil {
+			a.Logger.Println("Failed to encode cursor: ", err)
+			return nil, httpx.Internal(err)
+		}
+	}
+
+	return map[string]interface{}{
+		"users":       users,
+		"next_cursor": nextCursor,
+	}, nil
+}
+
+// CreateUser handles HTTP POST requests to add a new user to the database.
+func (a *App) CreateUser(r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodPost {
+		return nil, &httpx.HTTPError{Code: http.StatusMethodNotAllowed, Msg: "Invalid request method. Only POST is allowed"}
+	}
+
+	var req CreateUserRequest
+	if err := httpx.Bind(r, &req); err != nil {
+		return nil, httpx.BadRequest(err.Error())
+	}
+	username := req.Name
+
+	exists, err := a.checkUsernameExists(username)
+	if err != nil {
+		a.Logger.Println("Error checking username:", err)
+		return nil, httpx.Internal(err)
+	}
+	if exists {
+		return nil, httpx.Conflict("Username already exists")
+	}
+
+	if _, err := a.DB.Exec("INSERT INTO users (name) VALUES ($1)", username); err != nil {
+		a.Logger.Println("Failed to create user: ", err)
+		return nil, httpx.Internal(err)
+	}
+
+	return httpx.Created(map[string]interface{}{
+		"message": fmt.Sprintf("User %s created successfully", username),
+	}), nil
+}
+
+// Healthz reports whether the database is reachable. It returns a 503
+// HTTPError while the ping fails so load balancers can take the instance
+// out of rotation during a transient DB outage.
+func (a *App) Healthz(r *http.Request) (interface{}, error) {
+	if err := a.DB.Ping(); err != nil {
+		return nil, &httpx.HTTPError{Code: http.StatusServiceUnavailable, Msg: "database unavailable"}
+	}
+	return map[string]interface{}{"status": "ok"}, nil
+}
+
+// checkUsernameExists checks if a given username already exists in the database.
+func (a *App) checkUsernameExists(username string) (bool, error) {
+	var exists bool
+	err := a.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE name=$1)", username).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
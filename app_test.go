@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/zubairtahit/buggy_project/config"
+)
+
+func newTestApp(t *testing.T) (*App, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	app, teardown, err := NewApp(Options{DB: db, Config: config.Config{Host: "127.0.0.1", Port: 0}})
+	if err != nil {
+		t.Fatalf("failed to construct app: %v", err)
+	}
+
+	return app, mock, teardown
+}
+
+func TestGetUsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		setupMock  func(mock sqlmock.Sqlmock)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "returns users",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "name", "created_at"}).
+					AddRow(1, "alice", time.Unix(0, 0)).
+					AddRow(2, "bob", time.Unix(0, 0))
+				mock.ExpectQuery("SELECT id, name, created_at FROM users").WillReturnRows(rows)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"alice"`,
+		},
+		{
+			name: "query failure returns 500",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT id, name, created_at FROM users").WillReturnError(sqlmock.ErrCancelled)
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"error":true`,
+		},
+		{
+			name:       "invalid limit",
+			query:      "?limit=not-a-number",
+			setupMock:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"error":true`,
+		},
+		{
+			name:       "invalid cursor",
+			query:      "?cursor=not-valid-base64!!",
+			setupMock:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"error":true`,
+		},
+		{
+			name:  "limit above max is clamped",
+			query: "?limit=500",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "name", "created_at"}).
+					AddRow(1, "alice", time.Unix(0, 0))
+				mock.ExpectQuery(`SELECT id, name, created_at FROM users WHERE id > \$1 ORDER BY id ASC LIMIT 100`).
+					WithArgs(0).
+					WillReturnRows(rows)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"alice"`,
+		},
+		{
+			name:  "name_prefix filters results",
+			query: "?name_prefix=al",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "name", "created_at"}).
+					AddRow(1, "alice", time.Unix(0, 0))
+				mock.ExpectQuery(`SELECT id, name, created_at FROM users WHERE id > \$1 AND name LIKE \$2 ORDER BY id ASC LIMIT 20`).
+					WithArgs(0, "al%").
+					WillReturnRows(rows)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"alice"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mock, teardown := newTestApp(t)
+			defer teardown()
+			tt.setupMock(mock)
+
+			req := httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			app.Router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want substring %q", w.Body.String(), tt.wantBody)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet sqlmock expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/zubairtahit/buggy_project/internal/httpx"
+)
+
+// Role identifies what a user is allowed to do.
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+// User represents an account stored in the users table.
+type User struct {
+	ID           int
+	Name         string
+	PasswordHash string
+	Role         Role
+}
+
+// Claims are the custom JWT claims issued on login.
+type Claims struct {
+	UserID int  `json:"sub"`
+	Role   Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type contextKey string
+
+const userContextKey contextKey = "user"
+
+// dummyPasswordHash is a fixed, valid bcrypt hash used only to burn roughly
+// the same CPU time as a real bcrypt compare when a login fails because the
+// username doesn't exist (see Login).
+const dummyPasswordHash = "$2a$10$CwTycUXWue0Thq9StjUM0uQxTmrjOJ1EzWgOKf/A2kO7XxQmdCrMS"
+
+// jwtSecret returns the signing secret from the environment. Callers can
+// assume it's non-empty: NewApp validates JWT_SECRET once at startup via
+// checkJWTConfig, so the server never gets far enough to serve a request
+// without one.
+func jwtSecret() []byte {
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+// checkJWTConfig validates that JWT_SECRET is set. NewApp calls this once
+// at construction time so a missing secret fails the process at startup
+// instead of serving /login and admin requests with an empty signing key.
+func checkJWTConfig() error {
+	if os.Getenv("JWT_SECRET") == "" {
+		return fmt.Errorf("auth: JWT_SECRET environment variable must be set")
+	}
+	return nil
+}
+
+// tokenTTL returns how long issued tokens remain valid, configurable via
+// TOKEN_TTL (a Go duration string, e.g. "24h"). Defaults to 24 hours.
+func tokenTTL() time.Duration {
+	if raw := os.Getenv("TOKEN_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d
+		}
+	}
+	return 24 * time.Hour
+}
+
+// GenerateToken signs a JWT containing the user's id and role.
+func GenerateToken(u *User) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		UserID: u.ID,
+		Role:   u.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL())),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret())
+}
+
+// ParseToken validates the signature and expiry of tokenString and returns
+// its claims.
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return jwtSecret(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+// AuthMiddleware parses the Authorization: Bearer <token> header, validates
+// it, and injects the authenticated user into the request context before
+// calling next.
+func AuthMiddleware(next httpx.APIHandler) httpx.APIHandler {
+	return func(r *http.Request) (interface{}, error) {
+		header := r.Header.Get("Authorization")
+		if header == "" || !strings.HasPrefix(header, "Bearer ") {
+			return nil, &httpx.HTTPError{Code: http.StatusUnauthorized, Msg: "Missing or malformed Authorization header"}
+		}
+
+		claims, err := ParseToken(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			return nil, &httpx.HTTPError{Code: http.StatusUnauthorized, Msg: "Invalid or expired token"}
+		}
+
+		user := &User{ID: claims.UserID, Role: claims.Role}
+		ctx := context.WithValue(r.Context(), userContextKey, user)
+		return next(r.WithContext(ctx))
+	}
+}
+
+// RequireRole wraps next so that it only runs if the authenticated user in
+// the request context has the given role. AuthMiddleware must run first.
+func RequireRole(role Role, next httpx.APIHandler) httpx.APIHandler {
+	return func(r *http.Request) (interface{}, error) {
+		user, ok := userFromContext(r)
+		if !ok {
+			return nil, &httpx.HTTPError{Code: http.StatusUnauthorized, Msg: "Authentication required"}
+		}
+		if user.Role != role {
+			return nil, &httpx.HTTPError{Code: http.StatusForbidden, Msg: "Insufficient permissions"}
+		}
+		return next(r)
+	}
+}
+
+// userFromContext retrieves the user injected by AuthMiddleware.
+func userFromContext(r *http.Request) (*User, bool) {
+	user, ok := r.Context().Value(userContextKey).(*User)
+	return user, ok
+}
+
+// maxBcryptPasswordBytes is bcrypt's hard limit on input length. validator's
+// max tag counts runes, not bytes, so it can't enforce this on its own —
+// Register checks it directly against len(req.Password) before hashing.
+const maxBcryptPasswordBytes = 72
+
+// RegisterRequest is the payload accepted by (*App).Register.
+type RegisterRequest struct {
+	Name     string `json:"name" validate:"required,min=3,max=20,alphanum"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// LoginRequest is the payload accepted by (*App).Login.
+type LoginRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Register handles POST /register: it validates the submitted credentials,
+// hashes the password, and stores a new user with the default "user" role.
+func (a *App) Register(r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodPost {
+		return nil, &httpx.HTTPError{Code: http.StatusMethodNotAllowed, Msg: "Invalid request method. Only POST is allowed"}
+	}
+
+	var req RegisterRequest
+	if err := httpx.Bind(r, &req); err != nil {
+		return nil, httpx.BadRequest(err.Error())
+	}
+	if len(req.Password) > maxBcryptPasswordBytes {
+		return nil, httpx.BadRequest(fmt.Sprintf("password must be at most %d bytes", maxBcryptPasswordBytes))
+	}
+
+	exists, err := a.checkUsernameExists(req.Name)
+	if err != nil {
+		a.Logger.Println("Error checking username:", err)
+		return nil, httpx.Internal(err)
+	}
+	if exists {
+		return nil, httpx.Conflict("Username already exists")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		a.Logger.Println("Failed to hash password:", err)
+		return nil, httpx.Internal(err)
+	}
+
+	_, err = a.DB.Exec("INSERT INTO users (name, password_hash, role) VALUES ($1, $2, $3)", req.Name, string(hash), RoleUser)
+	if err != nil {
+		a.Logger.Println("Failed to create user: ", err)
+		return nil, httpx.Internal(err)
+	}
+
+	return httpx.Created(map[string]interface{}{
+		"message": fmt.Sprintf("User %s registered successfully", req.Name),
+	}), nil
+}
+
+// Login handles POST /login: it verifies the submitted credentials against
+// the stored bcrypt hash and, on success, returns a signed JWT.
+func (a *App) Login(r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodPost {
+		return nil, &httpx.HTTPError{Code: http.StatusMethodNotAllowed, Msg: "Invalid request method. Only POST is allowed"}
+	}
+
+	var req LoginRequest
+	if err := httpx.Bind(r, &req); err != nil {
+		return nil, httpx.BadRequest(err.Error())
+	}
+
+	var user User
+	err := a.DB.QueryRow("SELECT id, name, password_hash, role FROM users WHERE name=$1", req.Name).
+		Scan(&user.ID, &user.Name, &user.PasswordHash, &user.Role)
+	if err == sql.ErrNoRows {
+		// Run a dummy compare against a fixed hash so a nonexistent username
+		// takes about as long as a wrong password, instead of returning
+		// early and letting an attacker enumerate valid usernames by timing.
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(req.Password))
+		return nil, &httpx.HTTPError{Code: http.StatusUnauthorized, Msg: "Invalid username or password"}
+	} else if err != nil {
+		a.Logger.Println("Failed to look up user:", err)
+		return nil, httpx.Internal(err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, &httpx.HTTPError{Code: http.StatusUnauthorized, Msg: "Invalid username or password"}
+	}
+
+	token, err := GenerateToken(&user)
+	if err != nil {
+		a.Logger.Println("Failed to issue token:", err)
+		return nil, httpx.Internal(err)
+	}
+
+	return map[string]interface{}{
+		"token": token,
+	}, nil
+}
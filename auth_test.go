@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/zubairtahit/buggy_project/internal/httpx"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	validToken, err := GenerateToken(&User{ID: 1, Role: RoleUser})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	expiredClaims := Claims{
+		UserID: 1,
+		Role:   RoleUser,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-2 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-1 * time.Hour)),
+		},
+	}
+	expiredToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, expiredClaims).SignedString(jwtSecret())
+	if err != nil {
+		t.Fatalf("failed to sign expired token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"malformed header", "not-a-bearer-token", http.StatusUnauthorized},
+		{"invalid token", "Bearer garbage", http.StatusUnauthorized},
+		{"expired token", "Bearer " + expiredToken, http.StatusUnauthorized},
+		{"valid token", "Bearer " + validToken, http.StatusOK},
+	}
+
+	next := func(r *http.Request) (interface{}, error) {
+		return "ok", nil
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			httpx.Invoke(w, req, AuthMiddleware(next))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	next := func(r *http.Request) (interface{}, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		name       string
+		user       *User
+		wantStatus int
+	}{
+		{"no user in context", nil, http.StatusUnauthorized},
+		{"wrong role", &User{Role: RoleUser}, http.StatusForbidden},
+		{"matching role", &User{Role: RoleAdmin}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.user != nil {
+				req = req.WithContext(context.WithValue(req.Context(), userContextKey, tt.user))
+			}
+
+			w := httptest.NewRecorder()
+			httpx.Invoke(w, req, RequireRole(RoleAdmin, next))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateUserAuthorization(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	adminToken, err := GenerateToken(&User{ID: 1, Role: RoleAdmin})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	userToken, err := GenerateToken(&User{ID: 2, Role: RoleUser})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	unauthorized := []struct {
+		name       string
+		authHeader string
+		wantStatus int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"non-admin token", "Bearer " + userToken, http.StatusForbidden},
+	}
+
+	for _, tt := range unauthorized {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, teardown := newTestApp(t)
+			defer teardown()
+
+			form := url.Values{"name": {"validuser"}}
+			req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			w := httptest.NewRecorder()
+			app.Router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("admin token creates user", func(t *testing.T) {
+		app, mock, teardown := newTestApp(t)
+		defer teardown()
+
+		mock.ExpectQuery("SELECT EXISTS").WithArgs("validuser").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("INSERT INTO users").WithArgs("validuser").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		form := url.Values{"name": {"validuser"}}
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+
+		w := httptest.NewRecorder()
+		app.Router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("status = %d, want %d (body %s)", w.Code, http.StatusCreated, w.Body.String())
+		}
+	})
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name       string
+		form       url.Values
+		setupMock  func(mock sqlmock.Sqlmock)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			form: url.Values{"name": {"newuser"}, "password": {"supersecret"}},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS").WithArgs("newuser").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectExec("INSERT INTO users").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "username already exists",
+			form: url.Values{"name": {"newuser"}, "password": {"supersecret"}},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS").WithArgs("newuser").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "password too short",
+			form:       url.Values{"name": {"newuser"}, "password": {"short"}},
+			setupMock:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "password too long in bytes",
+			form:       url.Values{"name": {"newuser"}, "password": {strings.Repeat("é", 72)}},
+			setupMock:  func(mock sqlmock.Sqlmock) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mock, teardown := newTestApp(t)
+			defer teardown()
+			tt.setupMock(mock)
+
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			w := httptest.NewRecorder()
+			app.Router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt.GenerateFromPassword: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		setupMock  func(mock sqlmock.Sqlmock)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			form: url.Values{"name": {"alice"}, "password": {"correct-password"}},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "name", "password_hash", "role"}).
+					AddRow(1, "alice", string(hash), RoleUser)
+				mock.ExpectQuery("SELECT id, name, password_hash, role FROM users").
+					WithArgs("alice").WillReturnRows(rows)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "wrong password",
+			form: url.Values{"name": {"alice"}, "password": {"wrong-password"}},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "name", "password_hash", "role"}).
+					AddRow(1, "alice", string(hash), RoleUser)
+				mock.ExpectQuery("SELECT id, name, password_hash, role FROM users").
+					WithArgs("alice").WillReturnRows(rows)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "unknown user",
+			form: url.Values{"name": {"ghost"}, "password": {"whatever1"}},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT id, name, password_hash, role FROM users").
+					WithArgs("ghost").WillReturnError(sql.ErrNoRows)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mock, teardown := newTestApp(t)
+			defer teardown()
+			tt.setupMock(mock)
+
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			w := httptest.NewRecorder()
+			app.Router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	app, mock, teardown := newTestApp(t)
+	defer teardown()
+
+	mock.ExpectPing()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d (body %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	w = httptest.NewRecorder()
+	app.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d (body %s)", w.Code, http.StatusServiceUnavailable, w.Body.String())
+	}
+}
@@ -0,0 +1,105 @@
+// Package config loads the server's runtime configuration from a YAML file
+// with per-key environment variable overrides.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds everything the server needs to start: where to listen and
+// how to connect to the database.
+type Config struct {
+	DBDSN           string        `yaml:"db_dsn"`
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	InstanceName    string        `yaml:"instance_name"`
+	AllowAnon       bool          `yaml:"allow_anon"`
+	Debug           bool          `yaml:"debug"`
+}
+
+// Addr returns the host:port the HTTP server should listen on.
+func (c Config) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// defaults returns the configuration used when a key is set in neither the
+// config file nor the environment.
+func defaults() Config {
+	return Config{
+		DBDSN:           "user=postgres dbname=test sslmode=disable",
+		Port:            8080,
+		MaxOpenConns:    25,
+		MaxIdleConns:    25,
+		ConnMaxLifetime: 5 * time.Minute,
+	}
+}
+
+// Load reads the YAML file at path, falling back to defaults for keys it
+// doesn't set, then applies any BUGGY_* environment variable overrides. A
+// missing file is not an error; it just means defaults and env vars apply.
+func Load(path string) (Config, error) {
+	cfg := defaults()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return Config{}, fmt.Errorf("config: failed to read %s: %w", path, err)
+		}
+	} else if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("config: failed to parse %s: %w", path, err)
+	}
+
+	applyEnvOverrides(&cfg)
+
+	return cfg, nil
+}
+
+func applyEnvOverrides(cfg *Config) {
+	if v := os.Getenv("BUGGY_DB_DSN"); v != "" {
+		cfg.DBDSN = v
+	}
+	if v := os.Getenv("BUGGY_HOST"); v != "" {
+		cfg.Host = v
+	}
+	if v := os.Getenv("BUGGY_PORT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Port = n
+		}
+	}
+	if v := os.Getenv("BUGGY_MAX_OPEN_CONNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MaxOpenConns = n
+		}
+	}
+	if v := os.Getenv("BUGGY_MAX_IDLE_CONNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MaxIdleConns = n
+		}
+	}
+	if v := os.Getenv("BUGGY_CONN_MAX_LIFETIME"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.ConnMaxLifetime = d
+		}
+	}
+	if v := os.Getenv("BUGGY_INSTANCE_NAME"); v != "" {
+		cfg.InstanceName = v
+	}
+	if v := os.Getenv("BUGGY_ALLOW_ANON"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.AllowAnon = b
+		}
+	}
+	if v := os.Getenv("BUGGY_DEBUG"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.Debug = b
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Package db opens and verifies the server's database connection.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/zubairtahit/buggy_project/config"
+)
+
+// Teardown releases the resources acquired by Setup.
+type Teardown func()
+
+// Setup opens a connection pool per cfg and pings it with a short retry
+// and backoff loop before returning, so a transient DB startup race
+// doesn't take down the whole process.
+func Setup(cfg config.Config) (*sql.DB, Teardown, error) {
+	conn, err := sql.Open("postgres", cfg.DBDSN)
+	if err != nil {
+		return nil, nil, fmt.Errorf("db: failed to open connection: %w", err)
+	}
+
+	conn.SetMaxOpenConns(cfg.MaxOpenConns)
+	conn.SetMaxIdleConns(cfg.MaxIdleConns)
+	conn.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	if err := pingWithRetry(conn, 5, 500*time.Millisecond); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("db: failed to ping database: %w", err)
+	}
+
+	return conn, func() { conn.Close() }, nil
+}
+
+// pingWithRetry pings conn up to attempts times, doubling the backoff
+// between each failure, and returns the last error if none succeed.
+func pingWithRetry(conn *sql.DB, attempts int, backoff time.Duration) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = conn.Ping(); err == nil {
+			return nil
+		}
+		if i < attempts-1 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+// Package httpx provides small helpers for decoding and validating HTTP
+// request bodies on top of the standard library.
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = validator.New()
+
+// FieldError describes one struct-tag validation failure.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by Bind when v fails struct tag validation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = fmt.Sprintf("%s: %s", f.Field, f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Bind decodes r's body into v based on its Content-Type header
+// (application/json or application/x-www-form-urlencoded) and validates v
+// against its `validate` struct tags. On a validation failure it returns a
+// *ValidationError; on a malformed body it returns an error wrapping the
+// underlying decode error.
+func Bind(r *http.Request, v interface{}) error {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	switch mediaType {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+			return fmt.Errorf("httpx: failed to decode JSON body: %w", err)
+		}
+	case "application/x-www-form-urlencoded", "":
+		if err := r.ParseForm(); err != nil {
+			return fmt.Errorf("httpx: failed to parse form body: %w", err)
+		}
+		if err := bindForm(r, v); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("httpx: unsupported Content-Type %q", contentType)
+	}
+
+	if err := validate.Struct(v); err != nil {
+		verrs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return err
+		}
+		fields := make([]FieldError, len(verrs))
+		for i, fe := range verrs {
+			fields[i] = FieldError{
+				Field:   fe.Field(),
+				Message: fmt.Sprintf("failed on the %q tag", fe.Tag()),
+			}
+		}
+		return &ValidationError{Fields: fields}
+	}
+
+	return nil
+}
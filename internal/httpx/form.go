@@ -0,0 +1,55 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// bindForm populates the fields of v (a pointer to a struct) from r's
+// already-parsed form values, matching each field by its `json` tag name
+// (falling back to the field name) since that's the name callers already
+// use across the JSON and form-encoded variants of the same request.
+func bindForm(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("httpx: Bind target must be a pointer to a struct")
+	}
+
+	elem := rv.Elem()
+	t := elem.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			name = strings.Split(tag, ",")[0]
+		}
+
+		raw := r.FormValue(name)
+		if raw == "" {
+			continue
+		}
+
+		fv := elem.Field(i)
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(raw)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("httpx: field %q must be an integer", name)
+			}
+			fv.SetInt(n)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("httpx: field %q must be a boolean", name)
+			}
+			fv.SetBool(b)
+		}
+	}
+
+	return nil
+}
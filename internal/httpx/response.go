@@ -0,0 +1,90 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// APIResponse is the JSON envelope every handler response is wrapped in.
+type APIResponse struct {
+	Error   bool        `json:"error"`
+	Data    interface{} `json:"data,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
+
+// HTTPError is an error that knows which HTTP status code it should map to.
+type HTTPError struct {
+	Code int
+	Msg  string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Msg
+}
+
+// BadRequest builds an HTTPError for a 400 response.
+func BadRequest(msg string) *HTTPError {
+	return &HTTPError{Code: http.StatusBadRequest, Msg: msg}
+}
+
+// NotFound builds an HTTPError for a 404 response.
+func NotFound(msg string) *HTTPError {
+	return &HTTPError{Code: http.StatusNotFound, Msg: msg}
+}
+
+// Conflict builds an HTTPError for a 409 response.
+func Conflict(msg string) *HTTPError {
+	return &HTTPError{Code: http.StatusConflict, Msg: msg}
+}
+
+// Internal builds an HTTPError for a 500 response. The client only ever
+// sees a generic message; callers are expected to log err themselves
+// before returning it, since it may contain internal details (driver
+// errors, constraint names, ...) that shouldn't reach the caller.
+func Internal(err error) *HTTPError {
+	return &HTTPError{Code: http.StatusInternalServerError, Msg: "Internal server error"}
+}
+
+// APIHandler is the handler signature Invoke expects: return the response
+// data on success, or an error (ideally an *HTTPError) on failure. Wrap the
+// success value in Created to send a status other than 200.
+type APIHandler func(r *http.Request) (interface{}, error)
+
+// statusResult lets a handler pick its success status code; see Created.
+type statusResult struct {
+	code int
+	data interface{}
+}
+
+// Created wraps data so Invoke sends a 201 instead of the default 200,
+// for handlers that create a resource (e.g. registering or creating a user).
+func Created(data interface{}) interface{} {
+	return statusResult{code: http.StatusCreated, data: data}
+}
+
+// Invoke runs fn, marshals its result into an APIResponse envelope, and
+// writes it to w with the right status code, translating *HTTPError values
+// into their Code and falling back to 500 for anything else.
+func Invoke(w http.ResponseWriter, r *http.Request, fn APIHandler) {
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := fn(r)
+	if err != nil {
+		httpErr, ok := err.(*HTTPError)
+		if !ok {
+			httpErr = Internal(err)
+		}
+		w.WriteHeader(httpErr.Code)
+		json.NewEncoder(w).Encode(APIResponse{Error: true, Message: httpErr.Msg})
+		return
+	}
+
+	status := http.StatusOK
+	if sr, ok := data.(statusResult); ok {
+		status = sr.code
+		data = sr.data
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(APIResponse{Error: false, Data: data})
+}
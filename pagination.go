@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
+)
+
+// usersCursor is the opaque keyset position returned from and accepted by
+// GET /users for stable pagination over large tables.
+type usersCursor struct {
+	LastID    int       `json:"last_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// encodeUsersCursor base64-encodes c for use in a next_cursor response field.
+func encodeUsersCursor(c usersCursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+// decodeUsersCursor reverses encodeUsersCursor, rejecting anything that
+// doesn't round-trip cleanly.
+func decodeUsersCursor(raw string) (usersCursor, error) {
+	var c usersCursor
+	data, err := base64.URLEncoding.DecodeString(raw)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor encoding")
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("invalid cursor payload")
+	}
+	return c, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsersCursorRoundTrip(t *testing.T) {
+	want := usersCursor{LastID: 42, CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	encoded, err := encodeUsersCursor(want)
+	if err != nil {
+		t.Fatalf("encodeUsersCursor: %v", err)
+	}
+
+	got, err := decodeUsersCursor(encoded)
+	if err != nil {
+		t.Fatalf("decodeUsersCursor: %v", err)
+	}
+	if got.LastID != want.LastID || !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeUsersCursorInvalid(t *testing.T) {
+	if _, err := decodeUsersCursor("not-base64!!"); err == nil {
+		t.Error("expected an error for malformed cursor, got nil")
+	}
+}